@@ -0,0 +1,79 @@
+// Package config provides configuration for the video transcoding API,
+// loaded from environment variables.
+package config
+
+import "github.com/kelseyhightower/envconfig"
+
+// Config is the app's configuration, including the credentials and
+// defaults for every supported transcoding provider.
+type Config struct {
+	Server            *Server
+	SwaggerManifest   string `envconfig:"SWAGGER_MANIFEST_PATH"`
+	ElasticTranscoder *ElasticTranscoder
+	Bitmovin          *Bitmovin
+	Notifications     *Notifications
+	Ingest            *Ingest
+}
+
+// Server holds the basic HTTP server configuration.
+type Server struct {
+	Host string `envconfig:"HOST"`
+	Port string `envconfig:"PORT" default:"8080"`
+}
+
+// ElasticTranscoder holds the credentials and defaults for the Elastic
+// Transcoder provider.
+type ElasticTranscoder struct {
+	AccessKeyID     string `envconfig:"AWS_ACCESS_KEY_ID"`
+	SecretAccessKey string `envconfig:"AWS_SECRET_ACCESS_KEY"`
+	PipelineID      string `envconfig:"ELASTICTRANSCODER_PIPELINE_ID"`
+	Region          string `envconfig:"AWS_REGION"`
+}
+
+// Bitmovin holds the credentials and defaults for the Bitmovin provider.
+type Bitmovin struct {
+	APIKey          string `envconfig:"BITMOVIN_API_KEY"`
+	EncodingVersion string `envconfig:"BITMOVIN_ENCODING_VERSION" default:"v1"`
+	CloudRegion     string `envconfig:"BITMOVIN_CLOUD_REGION" default:"AWS_US_EAST_1"`
+	OutputBucket    string `envconfig:"BITMOVIN_OUTPUT_BUCKET"`
+	Timeout         uint   `envconfig:"BITMOVIN_TIMEOUT_SECONDS" default:"30"`
+}
+
+// Notifications holds the configuration for delivering job status updates
+// to client-supplied callback URLs.
+type Notifications struct {
+	// Type selects the notification backend: "sns" or "webhook".
+	Type string `envconfig:"NOTIFICATIONS_TYPE" default:"webhook"`
+
+	// SigningSecret is the default HMAC-SHA256 secret used to sign
+	// callback payloads when a tenant doesn't have one of its own.
+	SigningSecret string `envconfig:"NOTIFICATIONS_SIGNING_SECRET"`
+
+	// MaxRetries is the maximum number of delivery attempts for a single
+	// callback before it's given up on.
+	MaxRetries uint `envconfig:"NOTIFICATIONS_MAX_RETRIES" default:"10"`
+
+	// PollInterval is how often the webhook backend checks the provider
+	// for job status updates, in seconds.
+	PollInterval uint `envconfig:"NOTIFICATIONS_POLL_INTERVAL_SECONDS" default:"30"`
+
+	// SNSTopicARN is the topic Elastic Transcoder is configured to push
+	// Progressing/Complete/Warning/Error events to, when Type is "sns".
+	SNSTopicARN string `envconfig:"NOTIFICATIONS_SNS_TOPIC_ARN"`
+}
+
+// Ingest holds the configuration for pre-fetching non-S3 sources (YouTube,
+// generic HTTP) to a staging bucket before handing them to a provider.
+type Ingest struct {
+	AccessKeyID     string `envconfig:"INGEST_AWS_ACCESS_KEY_ID"`
+	SecretAccessKey string `envconfig:"INGEST_AWS_SECRET_ACCESS_KEY"`
+	Region          string `envconfig:"INGEST_AWS_REGION" default:"us-east-1"`
+	StagingBucket   string `envconfig:"INGEST_STAGING_BUCKET"`
+}
+
+// LoadConfig loads the configuration from environment variables.
+func LoadConfig() *Config {
+	var cfg Config
+	envconfig.Process("", &cfg)
+	return &cfg
+}
@@ -0,0 +1,155 @@
+// Package db provides the types and interfaces for persisting jobs and
+// presets used by the video transcoding API.
+package db
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is the error returned when the job is not found.
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrPresetNotFound is the error returned when the preset is not found.
+	ErrPresetNotFound = errors.New("preset not found")
+
+	// ErrPresetAlreadyExists is the error returned when the preset already
+	// exists.
+	ErrPresetAlreadyExists = errors.New("preset already exists")
+
+	// ErrPendingCallbackNotFound is the error returned when the pending
+	// callback is not found.
+	ErrPendingCallbackNotFound = errors.New("pending callback not found")
+)
+
+// Job represents the job that is persisted by the API.
+type Job struct {
+	ID                     string          `json:"jobId"`
+	ProviderName           string          `json:"providerName"`
+	ProviderJobID          string          `json:"providerJobId"`
+	SourceMedia            string          `json:"sourceMedia,omitempty"`
+	StatusCallbackURL      string          `json:"statusCallbackUrl,omitempty"`
+	StatusCallbackInterval uint            `json:"statusCallbackInterval,omitempty"`
+	CompletionCallbackURL  string          `json:"completionCallbackUrl,omitempty"`
+	StreamingParams        StreamingParams `json:"streamingParams,omitempty"`
+	CreationTime           string          `json:"creationTime,omitempty"`
+
+	// CallbackSecret is the HMAC secret used to sign this job's callback
+	// payloads. When empty, the notifier falls back to the API's
+	// configured default signing secret.
+	CallbackSecret string `json:"callbackSecret,omitempty"`
+
+	// IngestStatus and IngestProgress track the pre-fetch of non-s3
+	// sources (e.g. youtube://, http://) to the staging bucket, so
+	// GET /jobs/{id} can report that phase before transcoding starts.
+	IngestStatus   string    `json:"ingestStatus,omitempty"`
+	IngestProgress float64   `json:"ingestProgress,omitempty"`
+	MediaInfo      MediaInfo `json:"mediaInfo,omitempty"`
+
+	// Status records a terminal state reached outside of the provider's
+	// own bookkeeping, e.g. a client-initiated cancellation or a failure
+	// during ingestion/transcoding setup. It's empty while the job is in
+	// progress, in which case its status is queried live from the
+	// provider instead.
+	Status string `json:"status,omitempty"`
+}
+
+// MediaInfo carries the basic properties of a job's source media, as
+// resolved during ingestion.
+type MediaInfo struct {
+	Duration time.Duration `json:"duration,omitempty"`
+	Width    int64         `json:"width,omitempty"`
+	Height   int64         `json:"height,omitempty"`
+}
+
+// Ingest status values for Job.IngestStatus.
+const (
+	IngestStatusNotApplicable = ""
+	IngestStatusFetching      = "fetching"
+	IngestStatusFinished      = "finished"
+	IngestStatusFailed        = "failed"
+)
+
+// Job status values stored in Job.Status for terminal states reached
+// outside of the provider's own bookkeeping.
+const (
+	// JobStatusCanceled is stored once a job has been canceled through
+	// the API.
+	JobStatusCanceled = "canceled"
+
+	// JobStatusFailed is stored when ingestion or the provider's
+	// Transcode call fails for a job whose source required background
+	// ingestion, since there's no HTTP response left to report it on.
+	JobStatusFailed = "failed"
+)
+
+// StreamingParams is a subset of the provider streaming params that gets
+// persisted along with a job.
+type StreamingParams struct {
+	SegmentDuration  uint   `json:"segmentDuration,omitempty"`
+	PlaylistFileName string `json:"playlistFileName,omitempty"`
+	Protocol         string `json:"protocol,omitempty"`
+}
+
+// Preset represents a preset that is persisted by the API and later mapped
+// to provider-specific preset IDs.
+type Preset struct {
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	Container       string            `json:"container"`
+	Profile         string            `json:"profile"`
+	ProfileLevel    string            `json:"profileLevel"`
+	RateControl     string            `json:"rateControl"`
+	Video           VideoPreset       `json:"video"`
+	Audio           AudioPreset       `json:"audio"`
+	ProviderMapping map[string]string `json:"providerMapping"`
+}
+
+// VideoPreset holds the video settings of a Preset.
+type VideoPreset struct {
+	Width   string `json:"width"`
+	Height  string `json:"height"`
+	Codec   string `json:"codec"`
+	Bitrate string `json:"bitrate"`
+	GopSize string `json:"gopSize"`
+	GopMode string `json:"gopMode"`
+}
+
+// AudioPreset holds the audio settings of a Preset.
+type AudioPreset struct {
+	Codec   string `json:"codec"`
+	Bitrate string `json:"bitrate"`
+}
+
+// PendingCallback represents a job status callback that still needs to be
+// delivered to a client-supplied URL. It's persisted so that a restarted
+// API can resume delivery instead of losing track of in-flight callbacks.
+type PendingCallback struct {
+	ID          string `json:"id"`
+	JobID       string `json:"jobId"`
+	URL         string `json:"url"`
+	Kind        string `json:"kind"` // "status" or "completion"
+	Attempts    uint   `json:"attempts"`
+	NextAttempt string `json:"nextAttempt"`
+	Done        bool   `json:"done"`
+}
+
+// Storage is the interface that abstracts the persistence of jobs,
+// presets and pending notification callbacks.
+type Storage interface {
+	CreateJob(*Job) error
+	GetJob(id string) (*Job, error)
+	GetJobByProviderJobID(providerName, providerJobID string) (*Job, error)
+	DeleteJob(*Job) error
+	UpdateJob(id string, job *Job) error
+	CreatePreset(*Preset) error
+	GetPreset(name string) (*Preset, error)
+	DeletePreset(*Preset) error
+
+	CreatePendingCallback(*PendingCallback) error
+	ListDuePendingCallbacks(before string) ([]PendingCallback, error)
+	ListPendingCallbacksForJob(jobID string) ([]PendingCallback, error)
+	UpdatePendingCallback(*PendingCallback) error
+	DeletePendingCallback(*PendingCallback) error
+}
@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+const maxBackoff = 15 * time.Minute
+
+// backoff returns how long to wait before the next delivery attempt,
+// growing exponentially with the number of attempts already made.
+func backoff(attempts uint) time.Duration {
+	d := time.Duration(math.Pow(2, float64(attempts))) * time.Second
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+// deliver signs payload with secret and POSTs it to url, returning an error
+// if the request couldn't be made or the receiver didn't answer with a 2xx
+// status.
+func deliver(httpClient *http.Client, url, secret string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback %s responded with status %d", url, resp.StatusCode)
+	}
+	return nil
+}
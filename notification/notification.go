@@ -0,0 +1,68 @@
+// Package notification implements the delivery of job status updates to
+// client-supplied callback URLs.
+//
+// Two backends are provided: SNSNotifier, which relies on Elastic
+// Transcoder pushing job events to an SNS topic, and WebhookNotifier,
+// which polls the provider for status and persists pending callbacks so a
+// restarted API resumes deliveries instead of losing them.
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+)
+
+// SignatureHeader is the HTTP header carrying the HMAC-SHA256 signature of
+// the callback payload.
+const SignatureHeader = "X-Transcoding-Signature"
+
+// Notifier is implemented by the backends responsible for delivering job
+// status updates to the callback URLs stored on a db.Job. Register is
+// called synchronously while handling the job-creation request, and must
+// not block on the job actually finishing.
+type Notifier interface {
+	Register(job *db.Job) error
+
+	// Unregister stops any further delivery of callbacks for the given
+	// job, e.g. because the job was canceled.
+	Unregister(jobID string) error
+}
+
+// New creates the Notifier backend selected by cfg.Notifications.Type
+// ("sns" or "webhook", the default).
+func New(cfg *config.Config, dataStorage db.Storage) Notifier {
+	if cfg.Notifications.Type == "sns" {
+		return NewSNSNotifier(cfg, dataStorage)
+	}
+	return NewWebhookNotifier(cfg, dataStorage)
+}
+
+// secretFor returns the HMAC secret to sign job's callbacks with: the
+// job's own CallbackSecret when it set one, falling back to the API's
+// configured default.
+func secretFor(cfg *config.Config, job *db.Job) string {
+	if job.CallbackSecret != "" {
+		return job.CallbackSecret
+	}
+	return cfg.Notifications.SigningSecret
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of payload using
+// secret, to be sent in the SignatureHeader so receivers can verify that a
+// callback was generated by this API.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature is the correct HMAC-SHA256
+// signature of payload under secret.
+func VerifySignature(secret string, payload []byte, signature string) bool {
+	expected := Sign(secret, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
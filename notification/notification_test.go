@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+)
+
+func TestSecretFor(t *testing.T) {
+	cfg := &config.Config{
+		Notifications: &config.Notifications{SigningSecret: "default-secret"},
+	}
+	tests := []struct {
+		name string
+		job  *db.Job
+		want string
+	}{
+		{"falls back to default", &db.Job{}, "default-secret"},
+		{"uses job secret when set", &db.Job{CallbackSecret: "tenant-secret"}, "tenant-secret"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := secretFor(cfg, test.job); got != test.want {
+				t.Errorf("secretFor() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSignAndVerifySignature(t *testing.T) {
+	payload := []byte(`{"jobId":"abc123"}`)
+	signature := Sign("my-secret", payload)
+	if !VerifySignature("my-secret", payload, signature) {
+		t.Error("expected signature to verify with the same secret")
+	}
+	if VerifySignature("wrong-secret", payload, signature) {
+		t.Error("expected signature to fail to verify with a different secret")
+	}
+}
+
+func TestNewSelectsBackendByType(t *testing.T) {
+	tests := []struct {
+		notifType string
+		want      interface{}
+	}{
+		{"sns", &SNSNotifier{}},
+		{"webhook", &WebhookNotifier{}},
+		{"", &WebhookNotifier{}},
+	}
+	for _, test := range tests {
+		t.Run(test.notifType, func(t *testing.T) {
+			cfg := &config.Config{
+				Notifications:     &config.Notifications{Type: test.notifType},
+				ElasticTranscoder: &config.ElasticTranscoder{},
+			}
+			notifier := New(cfg, nil)
+			switch test.want.(type) {
+			case *SNSNotifier:
+				if _, ok := notifier.(*SNSNotifier); !ok {
+					t.Errorf("New() = %T, want *SNSNotifier", notifier)
+				}
+			case *WebhookNotifier:
+				if _, ok := notifier.(*WebhookNotifier); !ok {
+					t.Errorf("New() = %T, want *WebhookNotifier", notifier)
+				}
+			}
+		})
+	}
+}
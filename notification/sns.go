@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/elastictranscoder"
+	"github.com/aws/aws-sdk-go/service/elastictranscoder/elastictranscoderiface"
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+)
+
+// SNSNotifier is the push Notifier backend: it configures the Elastic
+// Transcoder pipeline to publish Progressing/Complete/Warning/Error events
+// to an SNS topic, which SNSHandler subscribes to and forwards as signed
+// callbacks. Register doesn't need to do any per-job bookkeeping beyond
+// making sure the pipeline is wired up, since the job itself is already in
+// storage, keyed by its provider job ID.
+type SNSNotifier struct {
+	c      elastictranscoderiface.ElasticTranscoderAPI
+	db     db.Storage
+	config *config.Config
+
+	configureOnce sync.Once
+	configureErr  error
+}
+
+// NewSNSNotifier creates a new SNSNotifier.
+func NewSNSNotifier(cfg *config.Config, dataStorage db.Storage) *SNSNotifier {
+	creds := credentials.NewStaticCredentials(cfg.ElasticTranscoder.AccessKeyID, cfg.ElasticTranscoder.SecretAccessKey, "")
+	awsSession := session.New(aws.NewConfig().WithCredentials(creds).WithRegion(cfg.ElasticTranscoder.Region))
+	return &SNSNotifier{
+		c:      elastictranscoder.New(awsSession),
+		db:     dataStorage,
+		config: cfg,
+	}
+}
+
+// Register ensures the pipeline's notifications are pointed at the
+// configured SNS topic, then returns. AWS takes care of delivering events
+// for every job submitted to that pipeline from then on.
+func (n *SNSNotifier) Register(job *db.Job) error {
+	n.configureOnce.Do(func() {
+		n.configureErr = n.configurePipelineNotifications()
+	})
+	return n.configureErr
+}
+
+// Unregister is a no-op for SNSNotifier: AWS only pushes events while the
+// provider job is active, and the caller is expected to have already asked
+// the provider to cancel it.
+func (n *SNSNotifier) Unregister(jobID string) error {
+	return nil
+}
+
+func (n *SNSNotifier) configurePipelineNotifications() error {
+	topic := aws.String(n.config.Notifications.SNSTopicARN)
+	_, err := n.c.UpdatePipelineNotifications(&elastictranscoder.UpdatePipelineNotificationsInput{
+		Id: aws.String(n.config.ElasticTranscoder.PipelineID),
+		Notifications: &elastictranscoder.Notifications{
+			Progressing: topic,
+			Completed:   topic,
+			Warning:     topic,
+			Error:       topic,
+		},
+	})
+	return err
+}
@@ -0,0 +1,187 @@
+package notification
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+)
+
+// snsMessage is the envelope SNS wraps every HTTP(S) delivery in. See
+// http://docs.aws.amazon.com/sns/latest/dg/sns-message-and-json-formats.html
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	Token            string `json:"Token"`
+}
+
+// elasticTranscoderEvent is the payload Elastic Transcoder publishes to the
+// configured SNS topic for Progressing/Complete/Warning/Error events.
+type elasticTranscoderEvent struct {
+	State string `json:"state"`
+	Input struct {
+		Key string `json:"key"`
+	} `json:"input"`
+	JobID      string `json:"jobId"`
+	PipelineID string `json:"pipelineId"`
+}
+
+// SNSHandler is an http.Handler that consumes the SNS topic Elastic
+// Transcoder is configured to publish to: it confirms the subscription,
+// verifies each notification's signature, looks up the job the event
+// refers to, and forwards it as a signed callback.
+type SNSHandler struct {
+	db         db.Storage
+	config     *config.Config
+	httpClient *http.Client
+}
+
+// NewSNSHandler creates a new SNSHandler.
+func NewSNSHandler(cfg *config.Config, dataStorage db.Storage) *SNSHandler {
+	return &SNSHandler{db: dataStorage, config: cfg, httpClient: &http.Client{}}
+}
+
+func (h *SNSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var msg snsMessage
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := verifySNSSignature(&msg); err != nil {
+		http.Error(w, fmt.Sprintf("invalid SNS signature: %s", err), http.StatusForbidden)
+		return
+	}
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		h.confirmSubscription(msg.SubscribeURL)
+	case "Notification":
+		h.handleNotification(msg.Message)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *SNSHandler) confirmSubscription(subscribeURL string) {
+	resp, err := h.httpClient.Get(subscribeURL)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+func (h *SNSHandler) handleNotification(message string) {
+	var event elasticTranscoderEvent
+	if err := json.Unmarshal([]byte(message), &event); err != nil {
+		return
+	}
+	job, err := h.db.GetJobByProviderJobID("elastictranscoder", event.JobID)
+	if err != nil {
+		return
+	}
+	status := map[string]interface{}{
+		"providerJobId": event.JobID,
+		"providerName":  job.ProviderName,
+		"status":        event.State,
+	}
+	secret := secretFor(h.config, job)
+	isCompletion := event.State != "PROGRESSING"
+	if job.StatusCallbackURL != "" {
+		deliver(h.httpClient, job.StatusCallbackURL, secret, status)
+	}
+	if isCompletion && job.CompletionCallbackURL != "" {
+		deliver(h.httpClient, job.CompletionCallbackURL, secret, status)
+	}
+}
+
+// signableFields lists, per message type, the fields that make up the
+// string SNS signs, in order.
+var signableFields = map[string][]string{
+	"Notification":             {"Message", "MessageId", "Subject", "Timestamp", "TopicArn", "Type"},
+	"SubscriptionConfirmation": {"Message", "MessageId", "SubscribeURL", "Timestamp", "Token", "TopicArn", "Type"},
+}
+
+// snsCertHostPattern matches the host of a genuine AWS SNS signing
+// certificate URL, e.g. sns.us-east-1.amazonaws.com or the China-region
+// amazonaws.com.cn variant. Anchoring on the parsed host (rather than
+// prefix/substring checks on the raw URL) stops an attacker from hosting
+// their own cert at a URL crafted to merely contain the right substrings.
+var snsCertHostPattern = regexp.MustCompile(`^sns\.[a-zA-Z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+func verifySNSSignature(msg *snsMessage) error {
+	certURL, err := url.Parse(msg.SigningCertURL)
+	if err != nil || certURL.Scheme != "https" || !snsCertHostPattern.MatchString(certURL.Host) {
+		return fmt.Errorf("untrusted signing certificate URL: %s", msg.SigningCertURL)
+	}
+	resp, err := http.Get(msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	certPEM, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return errors.New("could not decode signing certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+	pubKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("unexpected signing certificate public key type")
+	}
+	signature, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return err
+	}
+	signedString := canonicalSNSString(msg)
+	hashed := sha1.Sum([]byte(signedString))
+	return rsa.VerifyPKCS1v15(pubKey, crypto.SHA1, hashed[:], signature)
+}
+
+func canonicalSNSString(msg *snsMessage) string {
+	values := map[string]string{
+		"Message":      msg.Message,
+		"MessageId":    msg.MessageID,
+		"Subject":      msg.Subject,
+		"SubscribeURL": msg.SubscribeURL,
+		"Timestamp":    msg.Timestamp,
+		"Token":        msg.Token,
+		"TopicArn":     msg.TopicArn,
+		"Type":         msg.Type,
+	}
+	var b strings.Builder
+	for _, field := range signableFields[msg.Type] {
+		if value := values[field]; value != "" {
+			b.WriteString(field)
+			b.WriteString("\n")
+			b.WriteString(value)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
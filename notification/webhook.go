@@ -0,0 +1,183 @@
+package notification
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+// WebhookNotifier is the polling Notifier backend: it persists a
+// PendingCallback per callback URL a job declares, and a background
+// Run loop repeatedly checks the provider for status, delivers signed
+// callbacks, and reschedules with exponential backoff on failure. Because
+// state lives in storage rather than in an in-memory goroutine, a
+// restarted API resumes any callbacks that were still pending.
+type WebhookNotifier struct {
+	db         db.Storage
+	config     *config.Config
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier.
+func NewWebhookNotifier(cfg *config.Config, dataStorage db.Storage) *WebhookNotifier {
+	return &WebhookNotifier{
+		db:     dataStorage,
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// Register persists one PendingCallback per callback URL declared on job,
+// due immediately.
+func (n *WebhookNotifier) Register(job *db.Job) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	if job.StatusCallbackURL != "" {
+		err := n.db.CreatePendingCallback(&db.PendingCallback{
+			JobID:       job.ID,
+			URL:         job.StatusCallbackURL,
+			Kind:        "status",
+			NextAttempt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if job.CompletionCallbackURL != "" {
+		err := n.db.CreatePendingCallback(&db.PendingCallback{
+			JobID:       job.ID,
+			URL:         job.CompletionCallbackURL,
+			Kind:        "completion",
+			NextAttempt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unregister deletes every pending callback still queued for jobID, so a
+// canceled job doesn't keep getting polled and delivered.
+func (n *WebhookNotifier) Unregister(jobID string) error {
+	pending, err := n.db.ListPendingCallbacksForJob(jobID)
+	if err != nil {
+		return err
+	}
+	for i := range pending {
+		if err := n.db.DeletePendingCallback(&pending[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run polls storage for due pending callbacks on the configured interval
+// until ctx is done. It's meant to be started once, in its own goroutine,
+// when the API boots.
+func (n *WebhookNotifier) Run(ctx doneContext) error {
+	interval := time.Duration(n.config.Notifications.PollInterval) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			n.processDue()
+		}
+	}
+}
+
+// doneContext is the subset of context.Context that Run needs, so callers
+// can pass either golang.org/x/net/context or the standard library's
+// context without this package depending on which one the rest of the
+// service uses.
+type doneContext interface {
+	Done() <-chan struct{}
+}
+
+func (n *WebhookNotifier) processDue() {
+	due, err := n.db.ListDuePendingCallbacks(time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return
+	}
+	for i := range due {
+		n.attempt(&due[i])
+	}
+}
+
+func (n *WebhookNotifier) attempt(callback *db.PendingCallback) {
+	job, err := n.db.GetJob(callback.JobID)
+	if err != nil {
+		return
+	}
+	status, err := n.jobStatus(job)
+	if err != nil {
+		n.reschedule(callback)
+		return
+	}
+	if callback.Kind == "completion" &&
+		(status.Status == provider.StatusQueued || status.Status == provider.StatusStarted) {
+		callback.NextAttempt = time.Now().UTC().Add(time.Duration(n.config.Notifications.PollInterval) * time.Second).Format(time.RFC3339)
+		n.db.UpdatePendingCallback(callback)
+		return
+	}
+	secret := n.secretFor(job)
+	if err := deliver(n.httpClient, callback.URL, secret, status); err != nil {
+		n.reschedule(callback)
+		return
+	}
+	if callback.Kind == "status" && !status.Status.Terminal() {
+		callback.Attempts = 0
+		callback.NextAttempt = time.Now().UTC().Add(n.statusInterval(job)).Format(time.RFC3339)
+		n.db.UpdatePendingCallback(callback)
+		return
+	}
+	n.db.DeletePendingCallback(callback)
+}
+
+// statusInterval returns how often job's "status" callback should be
+// redelivered: the job's own StatusCallbackInterval when it set one, or the
+// backend's configured PollInterval otherwise.
+func (n *WebhookNotifier) statusInterval(job *db.Job) time.Duration {
+	if job.StatusCallbackInterval > 0 {
+		return time.Duration(job.StatusCallbackInterval) * time.Second
+	}
+	return time.Duration(n.config.Notifications.PollInterval) * time.Second
+}
+
+func (n *WebhookNotifier) reschedule(callback *db.PendingCallback) {
+	callback.Attempts++
+	if callback.Attempts >= n.config.Notifications.MaxRetries {
+		n.db.DeletePendingCallback(callback)
+		return
+	}
+	callback.NextAttempt = time.Now().UTC().Add(backoff(callback.Attempts)).Format(time.RFC3339)
+	n.db.UpdatePendingCallback(callback)
+}
+
+func (n *WebhookNotifier) jobStatus(job *db.Job) (*provider.JobStatus, error) {
+	factory, err := provider.GetProviderFactory(job.ProviderName)
+	if err != nil {
+		return nil, err
+	}
+	providerObj, err := factory(n.config)
+	if err != nil {
+		return nil, err
+	}
+	status, err := providerObj.JobStatus(job)
+	if err != nil {
+		return nil, err
+	}
+	status.ProviderName = job.ProviderName
+	return status, nil
+}
+
+func (n *WebhookNotifier) secretFor(job *db.Job) string {
+	return secretFor(n.config, job)
+}
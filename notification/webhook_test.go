@@ -0,0 +1,32 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+)
+
+func TestWebhookNotifierStatusInterval(t *testing.T) {
+	n := &WebhookNotifier{
+		config: &config.Config{
+			Notifications: &config.Notifications{PollInterval: 30},
+		},
+	}
+	tests := []struct {
+		name string
+		job  *db.Job
+		want time.Duration
+	}{
+		{"falls back to poll interval", &db.Job{}, 30 * time.Second},
+		{"uses job's own interval when set", &db.Job{StatusCallbackInterval: 5}, 5 * time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := n.statusInterval(test.job); got != test.want {
+				t.Errorf("statusInterval() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,538 @@
+// Package bitmovin provides a implementation of the provider that uses the
+// Bitmovin cloud encoding API for transcoding media files.
+//
+// It doesn't expose any public type. In order to use the provider, one must
+// import this package and then grab the factory from the provider package:
+//
+//     import (
+//         "github.com/nytm/video-transcoding-api/provider"
+//         "github.com/nytm/video-transcoding-api/provider/bitmovin"
+//     )
+//
+//     func UseProvider() {
+//         factory, err := provider.GetProviderFactory(bitmovin.Name)
+//         // handle err and use factory to get an instance of the provider.
+//     }
+package bitmovin
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+const (
+	// Name is the name used for registering the Bitmovin provider in the
+	// registry of providers.
+	Name = "bitmovin"
+
+	apiHost = "https://api.bitmovin.com"
+)
+
+var (
+	errBitmovinInvalidConfig = errors.New("invalid Bitmovin config. Please define the configuration entries in the config file or environment variables")
+
+	s3Pattern  = regexp.MustCompile(`^s3://`)
+	gcsPattern = regexp.MustCompile(`^gs://`)
+)
+
+func init() {
+	provider.Register(Name, bitmovinTranscodingProvider)
+}
+
+type bitmovinProvider struct {
+	c      *client
+	config *config.Bitmovin
+}
+
+func (p *bitmovinProvider) Transcode(job *db.Job, transcodeProfile provider.TranscodeProfile) (*provider.JobStatus, error) {
+	inputIDs, err := p.createInputs(transcodeProfile)
+	if err != nil {
+		return nil, err
+	}
+	var muxingIDs []string
+	var hlsOutputs []string
+	var dashOutputs []string
+	encodingID, err := p.createEncoding(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, output := range transcodeProfile.Outputs {
+		presetID, ok := output.Preset.ProviderMapping[Name]
+		if !ok {
+			return nil, provider.ErrPresetMapNotFound
+		}
+		configs, err := parsePresetID(presetID)
+		if err != nil {
+			return nil, err
+		}
+		outputPath := fmt.Sprintf("%s/%s", job.ID, output.FileName)
+		bitmovinOutput, err := p.createOutput(outputPath)
+		if err != nil {
+			return nil, err
+		}
+		streamIDs := make([]string, len(configs))
+		for i, cfg := range configs {
+			streamIDs[i], err = p.createStream(encodingID, inputIDs, bitmovinOutput, cfg.id)
+			if err != nil {
+				return nil, err
+			}
+		}
+		isHLS := output.Preset.Container == "m3u8" || output.Preset.Container == "ts"
+		isDASH := output.Preset.Container == "mpd" || transcodeProfile.StreamingParams.Protocol == "dash"
+		muxingID, err := p.createMuxing(encodingID, bitmovinOutput, streamIDs, output.Preset.Container, isHLS, isDASH)
+		if err != nil {
+			return nil, err
+		}
+		muxingIDs = append(muxingIDs, muxingID)
+		if isHLS {
+			hlsOutputs = append(hlsOutputs, muxingID)
+		}
+		if isDASH {
+			dashOutputs = append(dashOutputs, muxingID)
+		}
+	}
+	if len(hlsOutputs) > 0 {
+		playlistPath := fmt.Sprintf("%s/%s", job.ID, transcodeProfile.StreamingParams.PlaylistFileName)
+		if err := p.createHLSManifest(encodingID, playlistPath, hlsOutputs); err != nil {
+			return nil, err
+		}
+	}
+	if len(dashOutputs) > 0 {
+		manifestPath := fmt.Sprintf("%s/%s", job.ID, transcodeProfile.StreamingParams.PlaylistFileName)
+		if err := p.createDASHManifest(encodingID, manifestPath, dashOutputs); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.startEncoding(encodingID); err != nil {
+		return nil, err
+	}
+	return &provider.JobStatus{
+		ProviderName:  Name,
+		ProviderJobID: encodingID,
+		Status:        provider.StatusQueued,
+		ProviderStatus: map[string]interface{}{
+			"muxings": muxingIDs,
+		},
+	}, nil
+}
+
+func (p *bitmovinProvider) createEncoding(jobID string) (string, error) {
+	var resp struct {
+		Data struct {
+			Result struct {
+				ID string `json:"id"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	body := map[string]interface{}{
+		"name":       jobID,
+		"cloudRegion": p.config.CloudRegion,
+	}
+	if err := p.c.do("POST", "/encoding/encodings", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.Result.ID, nil
+}
+
+// createInputs creates one Bitmovin input per source in the transcode
+// profile, preserving order so the resulting streams concatenate the
+// sources (e.g. pre-roll + main content + post-roll) into a single output.
+func (p *bitmovinProvider) createInputs(transcodeProfile provider.TranscodeProfile) ([]string, error) {
+	sources := transcodeProfile.Sources
+	if len(sources) == 0 {
+		sources = []provider.Source{{SourceMedia: transcodeProfile.SourceMedia}}
+	}
+	inputIDs := make([]string, len(sources))
+	for i, source := range sources {
+		inputID, err := p.createInput(source.SourceMedia)
+		if err != nil {
+			return nil, err
+		}
+		inputIDs[i] = inputID
+	}
+	return inputIDs, nil
+}
+
+func (p *bitmovinProvider) createInput(sourceMedia string) (string, error) {
+	switch {
+	case s3Pattern.MatchString(sourceMedia):
+		path := strings.TrimPrefix(sourceMedia, "s3://")
+		parts := strings.SplitN(path, "/", 2)
+		var resp struct {
+			Data struct {
+				Result struct {
+					ID string `json:"id"`
+				} `json:"result"`
+			} `json:"data"`
+		}
+		body := map[string]interface{}{"bucketName": parts[0]}
+		if err := p.c.do("POST", "/encoding/inputs/s3", body, &resp); err != nil {
+			return "", err
+		}
+		return resp.Data.Result.ID, nil
+	case gcsPattern.MatchString(sourceMedia):
+		path := strings.TrimPrefix(sourceMedia, "gs://")
+		parts := strings.SplitN(path, "/", 2)
+		var resp struct {
+			Data struct {
+				Result struct {
+					ID string `json:"id"`
+				} `json:"result"`
+			} `json:"data"`
+		}
+		body := map[string]interface{}{"bucketName": parts[0]}
+		if err := p.c.do("POST", "/encoding/inputs/gcs", body, &resp); err != nil {
+			return "", err
+		}
+		return resp.Data.Result.ID, nil
+	default:
+		var resp struct {
+			Data struct {
+				Result struct {
+					ID string `json:"id"`
+				} `json:"result"`
+			} `json:"data"`
+		}
+		body := map[string]interface{}{"url": sourceMedia}
+		if err := p.c.do("POST", "/encoding/inputs/http", body, &resp); err != nil {
+			return "", err
+		}
+		return resp.Data.Result.ID, nil
+	}
+}
+
+// outputScheme and outputBucket report where encoded outputs are written:
+// p.config.OutputBucket may carry a "gs://" prefix to select a GCS output
+// instead of the default S3 one. HTTP isn't a meaningful output destination
+// for an encoder (there's nowhere for it to push the result to), so unlike
+// createInput this only supports S3 and GCS.
+func (p *bitmovinProvider) outputScheme() string {
+	if gcsPattern.MatchString(p.config.OutputBucket) {
+		return "gs"
+	}
+	return "s3"
+}
+
+func (p *bitmovinProvider) outputBucket() string {
+	bucket := p.config.OutputBucket
+	bucket = strings.TrimPrefix(bucket, "gs://")
+	bucket = strings.TrimPrefix(bucket, "s3://")
+	return bucket
+}
+
+func (p *bitmovinProvider) createOutput(path string) (string, error) {
+	var resp struct {
+		Data struct {
+			Result struct {
+				ID string `json:"id"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	endpoint := "/encoding/outputs/s3"
+	if p.outputScheme() == "gs" {
+		endpoint = "/encoding/outputs/gcs"
+	}
+	body := map[string]interface{}{"bucketName": p.outputBucket(), "outputPath": path}
+	if err := p.c.do("POST", endpoint, body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.Result.ID, nil
+}
+
+func (p *bitmovinProvider) createStream(encodingID string, inputIDs []string, outputID, codecConfigID string) (string, error) {
+	var resp struct {
+		Data struct {
+			Result struct {
+				ID string `json:"id"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	inputStreams := make([]map[string]interface{}, len(inputIDs))
+	for i, inputID := range inputIDs {
+		inputStreams[i] = map[string]interface{}{"inputId": inputID, "inputPath": "/", "position": i}
+	}
+	body := map[string]interface{}{
+		"codecConfigId": codecConfigID,
+		"inputStreams":  inputStreams,
+	}
+	path := fmt.Sprintf("/encoding/encodings/%s/streams", encodingID)
+	if err := p.c.do("POST", path, body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.Result.ID, nil
+}
+
+func (p *bitmovinProvider) createMuxing(encodingID, outputID string, streamIDs []string, container string, isHLS, isDASH bool) (string, error) {
+	var resp struct {
+		Data struct {
+			Result struct {
+				ID string `json:"id"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	muxingType := "mp4"
+	switch {
+	case isHLS:
+		muxingType = "ts"
+	case isDASH:
+		muxingType = "fmp4"
+	case container == "webm":
+		muxingType = "webm"
+	}
+	streams := make([]map[string]interface{}, len(streamIDs))
+	for i, streamID := range streamIDs {
+		streams[i] = map[string]interface{}{"streamId": streamID}
+	}
+	body := map[string]interface{}{
+		"streams": streams,
+		"outputs": []map[string]interface{}{
+			{"outputId": outputID, "outputPath": "/", "acl": []map[string]string{{"permission": "PUBLIC_READ"}}},
+		},
+	}
+	path := fmt.Sprintf("/encoding/encodings/%s/muxings/%s", encodingID, muxingType)
+	if err := p.c.do("POST", path, body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.Result.ID, nil
+}
+
+func (p *bitmovinProvider) createHLSManifest(encodingID, manifestPath string, muxingIDs []string) error {
+	var resp struct {
+		Data struct {
+			Result struct {
+				ID string `json:"id"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	body := map[string]interface{}{"manifestName": manifestPath}
+	if err := p.c.do("POST", "/encoding/manifests/hls", body, &resp); err != nil {
+		return err
+	}
+	manifestID := resp.Data.Result.ID
+	for _, muxingID := range muxingIDs {
+		variant := map[string]interface{}{"muxingId": muxingID, "uri": muxingID + ".m3u8"}
+		path := fmt.Sprintf("/encoding/manifests/hls/%s/media/ts", manifestID)
+		if err := p.c.do("POST", path, variant, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *bitmovinProvider) createDASHManifest(encodingID, manifestPath string, muxingIDs []string) error {
+	var resp struct {
+		Data struct {
+			Result struct {
+				ID string `json:"id"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	body := map[string]interface{}{"manifestName": manifestPath}
+	if err := p.c.do("POST", "/encoding/manifests/dash", body, &resp); err != nil {
+		return err
+	}
+	manifestID := resp.Data.Result.ID
+	for _, muxingID := range muxingIDs {
+		representation := map[string]interface{}{"muxingId": muxingID, "segmentPath": muxingID}
+		path := fmt.Sprintf("/encoding/manifests/dash/%s/representations/fmp4", manifestID)
+		if err := p.c.do("POST", path, representation, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *bitmovinProvider) startEncoding(encodingID string) error {
+	path := fmt.Sprintf("/encoding/encodings/%s/start", encodingID)
+	return p.c.do("POST", path, nil, nil)
+}
+
+func (p *bitmovinProvider) JobStatus(job *db.Job) (*provider.JobStatus, error) {
+	var resp struct {
+		Data struct {
+			Result struct {
+				Status   string  `json:"status"`
+				Progress float64 `json:"progress"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	path := fmt.Sprintf("/encoding/encodings/%s/status", job.ProviderJobID)
+	if err := p.c.do("GET", path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &provider.JobStatus{
+		ProviderName:  Name,
+		ProviderJobID: job.ProviderJobID,
+		Status:        p.statusMap(resp.Data.Result.Status),
+		Progress:      resp.Data.Result.Progress,
+		Output: provider.JobOutput{
+			Destination: fmt.Sprintf("%s://%s/%s", p.outputScheme(), p.outputBucket(), job.ID),
+		},
+	}, nil
+}
+
+func (p *bitmovinProvider) statusMap(bitmovinStatus string) provider.Status {
+	switch bitmovinStatus {
+	case "CREATED", "QUEUED":
+		return provider.StatusQueued
+	case "RUNNING":
+		return provider.StatusStarted
+	case "FINISHED":
+		return provider.StatusFinished
+	case "CANCELED":
+		return provider.StatusCanceled
+	default:
+		return provider.StatusFailed
+	}
+}
+
+// codecConfig identifies a single Bitmovin codec configuration created for
+// a preset, as encoded in the preset ID returned by CreatePreset.
+type codecConfig struct {
+	kind  string // "video" or "audio"
+	codec string
+	id    string
+}
+
+// encodePresetID packs one or more codec configurations into the single
+// opaque preset ID string that CreatePreset returns and ProviderMapping
+// stores, so DeletePreset and Transcode can later recover which Bitmovin
+// resources (and under which codec-specific path) to operate on.
+func encodePresetID(configs []codecConfig) string {
+	parts := make([]string, len(configs))
+	for i, cfg := range configs {
+		parts[i] = fmt.Sprintf("%s:%s:%s", cfg.kind, cfg.codec, cfg.id)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parsePresetID(presetID string) ([]codecConfig, error) {
+	parts := strings.Split(presetID, ",")
+	configs := make([]codecConfig, len(parts))
+	for i, part := range parts {
+		fields := strings.SplitN(part, ":", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid bitmovin preset id: %q", presetID)
+		}
+		configs[i] = codecConfig{kind: fields[0], codec: fields[1], id: fields[2]}
+	}
+	return configs, nil
+}
+
+func (p *bitmovinProvider) CreatePreset(preset provider.Preset) (string, error) {
+	videoCodec := strings.ToLower(preset.Video.Codec)
+	videoID, err := p.createVideoConfig(preset, videoCodec)
+	if err != nil {
+		return "", err
+	}
+	configs := []codecConfig{{kind: "video", codec: videoCodec, id: videoID}}
+	if preset.Audio.Codec != "" {
+		audioCodec := strings.ToLower(preset.Audio.Codec)
+		audioID, err := p.createAudioConfig(preset, audioCodec)
+		if err != nil {
+			return "", err
+		}
+		configs = append(configs, codecConfig{kind: "audio", codec: audioCodec, id: audioID})
+	}
+	return encodePresetID(configs), nil
+}
+
+func (p *bitmovinProvider) createVideoConfig(preset provider.Preset, codec string) (string, error) {
+	var resp struct {
+		Data struct {
+			Result struct {
+				ID string `json:"id"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	bitrate, _ := strconv.Atoi(preset.Video.Bitrate)
+	body := map[string]interface{}{
+		"name":    preset.Name,
+		"bitrate": bitrate,
+		"codec":   preset.Video.Codec,
+		"width":   preset.Video.Width,
+		"height":  preset.Video.Height,
+		"profile": strings.ToUpper(preset.Profile),
+	}
+	path := fmt.Sprintf("/encoding/configurations/video/%s", codec)
+	if err := p.c.do("POST", path, body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.Result.ID, nil
+}
+
+func (p *bitmovinProvider) createAudioConfig(preset provider.Preset, codec string) (string, error) {
+	var resp struct {
+		Data struct {
+			Result struct {
+				ID string `json:"id"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	bitrate, _ := strconv.Atoi(preset.Audio.Bitrate)
+	body := map[string]interface{}{
+		"name":    preset.Name,
+		"bitrate": bitrate,
+		"codec":   preset.Audio.Codec,
+	}
+	path := fmt.Sprintf("/encoding/configurations/audio/%s", codec)
+	if err := p.c.do("POST", path, body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.Result.ID, nil
+}
+
+func (p *bitmovinProvider) DeletePreset(presetID string) error {
+	configs, err := parsePresetID(presetID)
+	if err != nil {
+		return err
+	}
+	for _, cfg := range configs {
+		path := fmt.Sprintf("/encoding/configurations/%s/%s/%s", cfg.kind, cfg.codec, cfg.id)
+		if err := p.c.do("DELETE", path, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *bitmovinProvider) CancelJob(providerJobID string) error {
+	path := fmt.Sprintf("/encoding/encodings/%s/stop", providerJobID)
+	return p.c.do("POST", path, nil, nil)
+}
+
+func (p *bitmovinProvider) Healthcheck() error {
+	var resp struct {
+		Data struct {
+			Result struct {
+				Items []interface{} `json:"items"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	return p.c.do("GET", "/encoding/encodings?limit=1", nil, &resp)
+}
+
+func (p *bitmovinProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{
+		InputFormats:  []string{"h264", "h265"},
+		OutputFormats: []string{"mp4", "hls", "dash", "webm"},
+		Destinations:  []string{"s3", "gcs"},
+	}
+}
+
+func bitmovinTranscodingProvider(cfg *config.Config) (provider.TranscodingProvider, error) {
+	if cfg.Bitmovin.APIKey == "" {
+		return nil, errBitmovinInvalidConfig
+	}
+	return &bitmovinProvider{
+		c:      newClient(cfg.Bitmovin),
+		config: cfg.Bitmovin,
+	}, nil
+}
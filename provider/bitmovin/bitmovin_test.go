@@ -0,0 +1,44 @@
+package bitmovin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEncodeParsePresetID(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []codecConfig
+	}{
+		{
+			name:    "video only",
+			configs: []codecConfig{{kind: "video", codec: "h264", id: "abc123"}},
+		},
+		{
+			name: "video and audio",
+			configs: []codecConfig{
+				{kind: "video", codec: "h264", id: "abc123"},
+				{kind: "audio", codec: "aac", id: "def456"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			presetID := encodePresetID(test.configs)
+			configs, err := parsePresetID(presetID)
+			if err != nil {
+				t.Fatalf("parsePresetID(%q) returned error: %s", presetID, err)
+			}
+			if !reflect.DeepEqual(configs, test.configs) {
+				t.Errorf("parsePresetID(%q) = %#v, want %#v", presetID, configs, test.configs)
+			}
+		})
+	}
+}
+
+func TestParsePresetIDInvalid(t *testing.T) {
+	_, err := parsePresetID("video-h264-abc123")
+	if err == nil {
+		t.Error("expected error for malformed preset id, got nil")
+	}
+}
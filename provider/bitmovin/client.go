@@ -0,0 +1,75 @@
+package bitmovin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/nytm/video-transcoding-api/config"
+)
+
+// client is a small wrapper around the Bitmovin REST API. It only knows
+// about the handful of endpoints needed by this provider.
+type client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func newClient(cfg *config.Bitmovin) *client {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	version := cfg.EncodingVersion
+	if version == "" {
+		version = "v1"
+	}
+	return &client{
+		apiKey:  cfg.APIKey,
+		baseURL: fmt.Sprintf("%s/%s", apiHost, version),
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// do issues a request against the Bitmovin API, encoding body as JSON and
+// decoding the response into out, when provided.
+func (c *client) do(method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("bitmovin API error (status %d): %s", resp.StatusCode, respBody)
+	}
+	if out != nil && len(respBody) > 0 {
+		return json.Unmarshal(respBody, out)
+	}
+	return nil
+}
@@ -58,10 +58,14 @@ type awsProvider struct {
 
 func (p *awsProvider) Transcode(job *db.Job, transcodeProfile provider.TranscodeProfile) (*provider.JobStatus, error) {
 	var adaptiveStreamingOutputs []provider.TranscodeOutput
-	source := p.normalizeSource(transcodeProfile.SourceMedia)
 	params := elastictranscoder.CreateJobInput{
 		PipelineId: aws.String(p.config.PipelineID),
-		Input:      &elastictranscoder.JobInput{Key: aws.String(source)},
+	}
+	if len(transcodeProfile.Sources) > 0 {
+		params.Inputs = p.jobInputs(transcodeProfile.Sources)
+	} else {
+		source := p.normalizeSource(transcodeProfile.SourceMedia)
+		params.Input = &elastictranscoder.JobInput{Key: aws.String(source)}
 	}
 	params.Outputs = make([]*elastictranscoder.CreateJobOutput, len(transcodeProfile.Outputs))
 	for i, output := range transcodeProfile.Outputs {
@@ -119,6 +123,30 @@ func (p *awsProvider) Transcode(job *db.Job, transcodeProfile provider.Transcode
 	}, nil
 }
 
+func (p *awsProvider) jobInputs(sources []provider.Source) []*elastictranscoder.JobInput {
+	inputs := make([]*elastictranscoder.JobInput, len(sources))
+	for i, source := range sources {
+		input := &elastictranscoder.JobInput{Key: aws.String(p.normalizeSource(source.SourceMedia))}
+		if source.Container != "" {
+			input.Container = aws.String(source.Container)
+		}
+		if source.FrameRate != "" {
+			input.FrameRate = aws.String(source.FrameRate)
+		}
+		if source.StartTime != "" || source.Duration != "" {
+			input.TimeSpan = &elastictranscoder.TimeSpan{}
+			if source.StartTime != "" {
+				input.TimeSpan.StartTime = aws.String(source.StartTime)
+			}
+			if source.Duration != "" {
+				input.TimeSpan.Duration = aws.String(source.Duration)
+			}
+		}
+		inputs[i] = input
+	}
+	return inputs
+}
+
 func (p *awsProvider) normalizeSource(source string) string {
 	if s3Pattern.MatchString(source) {
 		source = strings.Replace(source, "s3://", "", 1)
@@ -0,0 +1,67 @@
+package elastictranscoder
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+func TestNormalizeSource(t *testing.T) {
+	p := &awsProvider{}
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"s3://bucket/path/to/video.mp4", "path/to/video.mp4"},
+		{"some-key.mp4", "some-key.mp4"},
+	}
+	for _, test := range tests {
+		if got := p.normalizeSource(test.source); got != test.want {
+			t.Errorf("normalizeSource(%q) = %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestJobInputs(t *testing.T) {
+	p := &awsProvider{}
+	sources := []provider.Source{
+		{SourceMedia: "s3://bucket/preroll.mp4"},
+		{
+			SourceMedia: "s3://bucket/main.mp4",
+			Container:   "mp4",
+			FrameRate:   "30",
+			StartTime:   "00:00:10.000",
+			Duration:    "00:01:00.000",
+		},
+	}
+	inputs := p.jobInputs(sources)
+	if len(inputs) != len(sources) {
+		t.Fatalf("jobInputs() returned %d inputs, want %d", len(inputs), len(sources))
+	}
+	if aws.StringValue(inputs[0].Key) != "preroll.mp4" {
+		t.Errorf("inputs[0].Key = %q, want %q", aws.StringValue(inputs[0].Key), "preroll.mp4")
+	}
+	if inputs[0].TimeSpan != nil {
+		t.Errorf("inputs[0].TimeSpan = %+v, want nil", inputs[0].TimeSpan)
+	}
+	second := inputs[1]
+	if aws.StringValue(second.Key) != "main.mp4" {
+		t.Errorf("inputs[1].Key = %q, want %q", aws.StringValue(second.Key), "main.mp4")
+	}
+	if aws.StringValue(second.Container) != "mp4" {
+		t.Errorf("inputs[1].Container = %q, want %q", aws.StringValue(second.Container), "mp4")
+	}
+	if aws.StringValue(second.FrameRate) != "30" {
+		t.Errorf("inputs[1].FrameRate = %q, want %q", aws.StringValue(second.FrameRate), "30")
+	}
+	if second.TimeSpan == nil {
+		t.Fatal("inputs[1].TimeSpan = nil, want non-nil")
+	}
+	if aws.StringValue(second.TimeSpan.StartTime) != "00:00:10.000" {
+		t.Errorf("inputs[1].TimeSpan.StartTime = %q, want %q", aws.StringValue(second.TimeSpan.StartTime), "00:00:10.000")
+	}
+	if aws.StringValue(second.TimeSpan.Duration) != "00:01:00.000" {
+		t.Errorf("inputs[1].TimeSpan.Duration = %q, want %q", aws.StringValue(second.TimeSpan.Duration), "00:01:00.000")
+	}
+}
@@ -14,7 +14,7 @@ func (*fakeProvider) Transcode(*db.Job, TranscodeProfile) (*JobStatus, error) {
 	return nil, nil
 }
 
-func (*fakeProvider) JobStatus(string) (*JobStatus, error) {
+func (*fakeProvider) JobStatus(*db.Job) (*JobStatus, error) {
 	return nil, nil
 }
 
@@ -26,6 +26,10 @@ func (*fakeProvider) DeletePreset(string) error {
 	return nil
 }
 
+func (*fakeProvider) CancelJob(string) error {
+	return nil
+}
+
 func (f *fakeProvider) Healthcheck() error {
 	return f.healthErr
 }
@@ -0,0 +1,69 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"golang.org/x/net/context"
+
+	"github.com/nytm/video-transcoding-api/config"
+)
+
+// httpFetcher streams a generic HTTP(S) URL straight into the staging
+// bucket via a multipart upload, without buffering it on disk.
+type httpFetcher struct {
+	config   *config.Ingest
+	uploader *s3manager.Uploader
+}
+
+func newHTTPFetcher(cfg *config.Ingest) *httpFetcher {
+	creds := credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	awsSession := session.New(aws.NewConfig().WithCredentials(creds).WithRegion(cfg.Region))
+	return &httpFetcher{config: cfg, uploader: s3manager.NewUploader(awsSession)}
+}
+
+func (f *httpFetcher) Fetch(ctx context.Context, sourceURI string) (string, MediaInfo, error) {
+	req, err := http.NewRequest("GET", sourceURI, nil)
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", MediaInfo{}, fmt.Errorf("ingest: fetching %s returned status %d", sourceURI, resp.StatusCode)
+	}
+	key := f.stagingKey(sourceURI)
+	_, err = f.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(f.config.StagingBucket),
+		Key:    aws.String(key),
+		Body:   resp.Body,
+	})
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	return fmt.Sprintf("s3://%s/%s", f.config.StagingBucket, key), MediaInfo{}, nil
+}
+
+func (f *httpFetcher) stagingKey(sourceURI string) string {
+	parsed, err := url.Parse(sourceURI)
+	fileName := "source"
+	if err == nil {
+		fileName = path.Base(parsed.Path)
+	}
+	// Hash the full source URI into the key so two different URLs that
+	// happen to share a basename (e.g. the same file name served from
+	// different hosts) don't stage to, and overwrite, the same S3 object.
+	digest := sha256.Sum256([]byte(sourceURI))
+	return fmt.Sprintf("ingest/http/%x/%s", digest[:8], strings.Trim(fileName, "/"))
+}
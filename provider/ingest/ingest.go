@@ -0,0 +1,57 @@
+// Package ingest resolves non-S3 job sources (YouTube videos, generic HTTP
+// URLs) to an S3 object that providers can transcode directly, so clients
+// can submit a job without a separate download step.
+package ingest
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/nytm/video-transcoding-api/config"
+)
+
+// ErrUnsupportedScheme is returned by NewFetcher when the source URI's
+// scheme doesn't match any known Fetcher.
+var ErrUnsupportedScheme = errors.New("unsupported source scheme")
+
+const (
+	youtubeScheme = "youtube://"
+	httpScheme    = "http://"
+	httpsScheme   = "https://"
+	s3Scheme      = "s3://"
+)
+
+// MediaInfo carries the basic properties of the fetched media, as resolved
+// during ingestion.
+type MediaInfo struct {
+	Duration time.Duration
+	Width    int64
+	Height   int64
+}
+
+// Fetcher resolves sourceURI and stages its media in S3, returning the
+// resulting s3:// URI.
+type Fetcher interface {
+	Fetch(ctx context.Context, sourceURI string) (s3URI string, mediaInfo MediaInfo, err error)
+}
+
+// IsS3Source reports whether sourceURI is already an s3:// URI and
+// therefore doesn't need ingestion.
+func IsS3Source(sourceURI string) bool {
+	return strings.HasPrefix(sourceURI, s3Scheme)
+}
+
+// NewFetcher returns the Fetcher capable of handling sourceURI's scheme.
+func NewFetcher(cfg *config.Ingest, sourceURI string) (Fetcher, error) {
+	switch {
+	case strings.HasPrefix(sourceURI, youtubeScheme):
+		return newYouTubeFetcher(cfg), nil
+	case strings.HasPrefix(sourceURI, httpScheme), strings.HasPrefix(sourceURI, httpsScheme):
+		return newHTTPFetcher(cfg), nil
+	default:
+		return nil, ErrUnsupportedScheme
+	}
+}
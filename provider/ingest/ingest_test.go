@@ -0,0 +1,59 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/nytm/video-transcoding-api/config"
+)
+
+func TestIsS3Source(t *testing.T) {
+	tests := []struct {
+		sourceURI string
+		want      bool
+	}{
+		{"s3://bucket/key.mp4", true},
+		{"youtube://dQw4w9WgXcQ", false},
+		{"http://example.com/video.mp4", false},
+		{"https://example.com/video.mp4", false},
+	}
+	for _, test := range tests {
+		if got := IsS3Source(test.sourceURI); got != test.want {
+			t.Errorf("IsS3Source(%q) = %v, want %v", test.sourceURI, got, test.want)
+		}
+	}
+}
+
+func TestNewFetcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		sourceURI string
+		wantType  Fetcher
+		wantErr   error
+	}{
+		{"youtube", "youtube://dQw4w9WgXcQ", &youtubeFetcher{}, nil},
+		{"http", "http://example.com/video.mp4", &httpFetcher{}, nil},
+		{"https", "https://example.com/video.mp4", &httpFetcher{}, nil},
+		{"unsupported scheme", "ftp://example.com/video.mp4", nil, ErrUnsupportedScheme},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetcher, err := NewFetcher(&config.Ingest{}, test.sourceURI)
+			if err != test.wantErr {
+				t.Fatalf("NewFetcher(%q) error = %v, want %v", test.sourceURI, err, test.wantErr)
+			}
+			if test.wantType != nil {
+				gotType := fetcher
+				switch test.wantType.(type) {
+				case *youtubeFetcher:
+					if _, ok := gotType.(*youtubeFetcher); !ok {
+						t.Errorf("NewFetcher(%q) = %T, want *youtubeFetcher", test.sourceURI, gotType)
+					}
+				case *httpFetcher:
+					if _, ok := gotType.(*httpFetcher); !ok {
+						t.Errorf("NewFetcher(%q) = %T, want *httpFetcher", test.sourceURI, gotType)
+					}
+				}
+			}
+		})
+	}
+}
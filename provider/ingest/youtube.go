@@ -0,0 +1,131 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"golang.org/x/net/context"
+
+	"github.com/nytm/video-transcoding-api/config"
+)
+
+const videoInfoURL = "https://www.youtube.com/get_video_info"
+
+// youtubeFetcher resolves a youtube://<videoID> source to its best
+// available progressive (muxed audio+video) stream and stages it in the
+// configured bucket.
+type youtubeFetcher struct {
+	config     *config.Ingest
+	uploader   *s3manager.Uploader
+	httpClient *http.Client
+}
+
+func newYouTubeFetcher(cfg *config.Ingest) *youtubeFetcher {
+	creds := credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	awsSession := session.New(aws.NewConfig().WithCredentials(creds).WithRegion(cfg.Region))
+	return &youtubeFetcher{
+		config:     cfg,
+		uploader:   s3manager.NewUploader(awsSession),
+		httpClient: http.DefaultClient,
+	}
+}
+
+func (f *youtubeFetcher) Fetch(ctx context.Context, sourceURI string) (string, MediaInfo, error) {
+	videoID := strings.TrimPrefix(sourceURI, youtubeScheme)
+	streamURL, mediaInfo, err := f.resolveProgressiveStream(ctx, videoID)
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	req, err := http.NewRequest("GET", streamURL, nil)
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	resp, err := f.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", MediaInfo{}, fmt.Errorf("ingest: fetching youtube video %q returned status %d", videoID, resp.StatusCode)
+	}
+	key := fmt.Sprintf("ingest/youtube/%s.mp4", videoID)
+	_, err = f.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(f.config.StagingBucket),
+		Key:    aws.String(key),
+		Body:   resp.Body,
+	})
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	return fmt.Sprintf("s3://%s/%s", f.config.StagingBucket, key), mediaInfo, nil
+}
+
+// progressiveFormat is the subset of a YouTube player response's streaming
+// formats that this package cares about.
+type progressiveFormat struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mimeType"`
+	Bitrate     int    `json:"bitrate"`
+	Width       int64  `json:"width"`
+	Height      int64  `json:"height"`
+	ApproxDurMs string `json:"approxDurationMs"`
+}
+
+// resolveProgressiveStream fetches the video's player info and picks the
+// highest-bitrate format that carries both audio and video (no DASH
+// adaptive-only streams), since those can be handed straight to a provider.
+func (f *youtubeFetcher) resolveProgressiveStream(ctx context.Context, videoID string) (string, MediaInfo, error) {
+	query := url.Values{
+		"video_id": {videoID},
+		"html5":    {"1"},
+	}
+	req, err := http.NewRequest("GET", videoInfoURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	resp, err := f.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", MediaInfo{}, err
+	}
+	var playerResponse struct {
+		StreamingData struct {
+			Formats []progressiveFormat `json:"formats"`
+		} `json:"streamingData"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("player_response")), &playerResponse); err != nil {
+		return "", MediaInfo{}, fmt.Errorf("ingest: could not parse player response for video %q: %s", videoID, err)
+	}
+	formats := playerResponse.StreamingData.Formats
+	if len(formats) == 0 {
+		return "", MediaInfo{}, fmt.Errorf("ingest: no progressive streams found for video %q", videoID)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i].Bitrate > formats[j].Bitrate })
+	best := formats[0]
+	durationMs, _ := strconv.Atoi(best.ApproxDurMs)
+	mediaInfo := MediaInfo{
+		Width:  best.Width,
+		Height: best.Height,
+	}
+	mediaInfo.Duration = time.Duration(durationMs) * time.Millisecond
+	return best.URL, mediaInfo, nil
+}
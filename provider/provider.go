@@ -0,0 +1,242 @@
+// Package provider defines interfaces to be implemented by providers of
+// video transcoding.
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+)
+
+var (
+	// ErrPresetMapNotFound is the error returned when the preset map is not
+	// found in the provider.
+	ErrPresetMapNotFound = errors.New("preset map not found")
+
+	// ErrPresetNotFound is the error returned when the preset is not found
+	// in the provider.
+	ErrPresetNotFound = errors.New("preset not found")
+
+	// ErrProviderNotFound is the error returned when the provider is not
+	// registered.
+	ErrProviderNotFound = errors.New("provider not found")
+
+	// ErrNotImplemented is returned by providers that don't support a
+	// given optional operation, such as CancelJob.
+	ErrNotImplemented = errors.New("not implemented")
+
+	providersMutex sync.RWMutex
+	providers      = make(map[string]Factory)
+)
+
+// Factory is the function responsible for creating the instance of a
+// TranscodingProvider.
+type Factory func(cfg *config.Config) (TranscodingProvider, error)
+
+// InvalidConfigError is the error returned when the provider gets an invalid
+// config.
+type InvalidConfigError string
+
+func (e InvalidConfigError) Error() string {
+	return string(e)
+}
+
+// JobNotFoundError is the error returned when the given job is not found in
+// the provider.
+type JobNotFoundError struct {
+	ID string
+}
+
+func (err JobNotFoundError) Error() string {
+	return fmt.Sprintf("job not found: %s", err.ID)
+}
+
+// TranscodingProvider represents the functionality of a provider of video
+// transcoding.
+type TranscodingProvider interface {
+	Transcode(job *db.Job, transcodeProfile TranscodeProfile) (*JobStatus, error)
+	JobStatus(job *db.Job) (*JobStatus, error)
+	CreatePreset(preset Preset) (string, error)
+	DeletePreset(presetID string) error
+	CancelJob(providerJobID string) error
+	Healthcheck() error
+	Capabilities() Capabilities
+}
+
+// Status is the status of a transcoding job.
+type Status string
+
+const (
+	// StatusQueued is the status for a job that is queued on the provider,
+	// but not yet processing.
+	StatusQueued = Status("queued")
+
+	// StatusStarted is the status for a job that is being processed.
+	StatusStarted = Status("started")
+
+	// StatusFinished is the status for a job that finished successfully.
+	StatusFinished = Status("finished")
+
+	// StatusFailed is the status for a job that failed to complete.
+	StatusFailed = Status("failed")
+
+	// StatusCanceled is the status for a job that was canceled before it
+	// finished.
+	StatusCanceled = Status("canceled")
+)
+
+// Terminal reports whether a job in this status is done processing and
+// won't transition to another status.
+func (s Status) Terminal() bool {
+	switch s {
+	case StatusFinished, StatusFailed, StatusCanceled:
+		return true
+	}
+	return false
+}
+
+// JobStatus is the representation of the status of a job, returned by
+// querying the transcoding providers.
+type JobStatus struct {
+	ProviderJobID  string                 `json:"providerJobId,omitempty"`
+	ProviderName   string                 `json:"providerName"`
+	Status         Status                 `json:"status,omitempty"`
+	Progress       float64                `json:"progress"`
+	ProviderStatus map[string]interface{} `json:"providerStatus,omitempty"`
+	StatusMessage  string                 `json:"statusMessage,omitempty"`
+	MediaInfo      MediaInfo              `json:"mediaInfo,omitempty"`
+	Output         JobOutput              `json:"output"`
+}
+
+// JobOutput represents the output of a transcoding job.
+type JobOutput struct {
+	Destination string       `json:"destination,omitempty"`
+	Files       []OutputFile `json:"files,omitempty"`
+}
+
+// OutputFile represents a file generated by a transcoding job.
+type OutputFile struct {
+	Path       string `json:"path,omitempty"`
+	Container  string `json:"container,omitempty"`
+	VideoCodec string `json:"videoCodec,omitempty"`
+	Width      int64  `json:"width,omitempty"`
+	Height     int64  `json:"height,omitempty"`
+}
+
+// MediaInfo represents information about the media available in a job or in
+// a given source.
+type MediaInfo struct {
+	Duration time.Duration `json:"duration,omitempty"`
+	Height   int64         `json:"height,omitempty"`
+	Width    int64         `json:"width,omitempty"`
+}
+
+// Capabilities represents the capabilities of a given provider.
+type Capabilities struct {
+	InputFormats  []string `json:"inputFormats"`
+	OutputFormats []string `json:"outputFormats"`
+	Destinations  []string `json:"destinations"`
+}
+
+// StreamingParams represents the params for HLS/DASH adaptive streaming
+// outputs of a transcoding job.
+type StreamingParams struct {
+	SegmentDuration  uint   `json:"segmentDuration,omitempty"`
+	PlaylistFileName string `json:"playlistFileName,omitempty"`
+	Protocol         string `json:"protocol,omitempty"`
+}
+
+// TranscodeOutput is a single output requested for a transcoding job.
+type TranscodeOutput struct {
+	Preset   Preset `json:"preset"`
+	FileName string `json:"fileName"`
+}
+
+// Source represents a single input to be concatenated into a transcoding
+// job's output. When a profile declares more than one Source, providers
+// that support it stitch them together, in order, into each output (e.g.
+// pre-roll + main content + post-roll).
+type Source struct {
+	SourceMedia string `json:"sourceMedia"`
+	StartTime   string `json:"startTime,omitempty"`
+	Duration    string `json:"duration,omitempty"`
+	FrameRate   string `json:"frameRate,omitempty"`
+	Container   string `json:"container,omitempty"`
+}
+
+// TranscodeProfile has the set of options used to configure a new
+// transcoding job.
+type TranscodeProfile struct {
+	SourceMedia     string            `json:"sourceMedia"`
+	Sources         []Source          `json:"sources,omitempty"`
+	Presets         []db.Preset       `json:"presets,omitempty"`
+	Outputs         []TranscodeOutput `json:"outputs,omitempty"`
+	StreamingParams StreamingParams   `json:"streamingParams,omitempty"`
+}
+
+// Preset is the generic representation of a preset.
+type Preset struct {
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	Container       string            `json:"container"`
+	Profile         string            `json:"profile"`
+	ProfileLevel    string            `json:"profileLevel"`
+	RateControl     string            `json:"rateControl"`
+	Video           VideoPreset       `json:"video"`
+	Audio           AudioPreset       `json:"audio"`
+	ProviderMapping map[string]string `json:"providerMapping"`
+}
+
+// VideoPreset sets the video parameters of a preset.
+type VideoPreset struct {
+	Width   string `json:"width"`
+	Height  string `json:"height"`
+	Codec   string `json:"codec"`
+	Bitrate string `json:"bitrate"`
+	GopSize string `json:"gopSize"`
+	GopMode string `json:"gopMode"`
+}
+
+// AudioPreset sets the audio parameters of a preset.
+type AudioPreset struct {
+	Codec   string `json:"codec"`
+	Bitrate string `json:"bitrate"`
+}
+
+// Register registers a new provider in the internal list of providers.
+func Register(name string, factory Factory) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	providers[name] = factory
+}
+
+// GetProviderFactory looks up the list of registered providers and returns
+// the factory function for the given name, or ErrProviderNotFound if no
+// provider is registered with that name.
+func GetProviderFactory(name string) (Factory, error) {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+	factory, ok := providers[name]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+	return factory, nil
+}
+
+// ListProviders returns the names of the currently registered providers, in
+// alphabetical order.
+func ListProviders() []string {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
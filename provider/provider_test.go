@@ -0,0 +1,21 @@
+package provider
+
+import "testing"
+
+func TestStatusTerminal(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   bool
+	}{
+		{StatusQueued, false},
+		{StatusStarted, false},
+		{StatusFinished, true},
+		{StatusFailed, true},
+		{StatusCanceled, true},
+	}
+	for _, test := range tests {
+		if got := test.status.Terminal(); got != test.want {
+			t.Errorf("Status(%q).Terminal() = %v, want %v", test.status, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+var errProviderNotGiven = errors.New("provider not given")
+
+// sourceInput is a single input of a (possibly multi-input) transcoding
+// job, as accepted in newTranscodeJobInput.Payload.Sources.
+type sourceInput struct {
+	SourceMedia string `json:"sourceMedia"`
+	StartTime   string `json:"startTime,omitempty"`
+	Duration    string `json:"duration,omitempty"`
+	FrameRate   string `json:"frameRate,omitempty"`
+	Container   string `json:"container,omitempty"`
+}
+
+// newTranscodeJobInput is the payload accepted by the newTranscodeJob
+// handler.
+type newTranscodeJobInput struct {
+	Payload struct {
+		Provider               string                   `json:"provider"`
+		Source                 string                   `json:"source"`
+		Sources                []sourceInput            `json:"sources,omitempty"`
+		Presets                []string                 `json:"presets"`
+		StreamingParams        provider.StreamingParams `json:"streamingParams,omitempty"`
+		StatusCallbackURL      string                   `json:"statusCallbackUrl,omitempty"`
+		StatusCallbackInterval uint                     `json:"statusCallbackInterval,omitempty"`
+		CompletionCallbackURL  string                   `json:"completionCallbackUrl,omitempty"`
+		CallbackSecret         string                   `json:"callbackSecret,omitempty"`
+	}
+}
+
+// ProviderFactory decodes the request body and returns the factory for the
+// requested provider.
+func (input *newTranscodeJobInput) ProviderFactory(body io.Reader) (provider.Factory, error) {
+	err := json.NewDecoder(body).Decode(&input.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if input.Payload.Provider == "" {
+		return nil, errProviderNotGiven
+	}
+	return provider.GetProviderFactory(input.Payload.Provider)
+}
+
+// getTranscodeJobInput is the set of parameters accepted by the
+// getTranscodeJob and cancelTranscodeJob handlers.
+type getTranscodeJobInput struct {
+	JobID string
+}
+
+func (input *getTranscodeJobInput) loadParams(params map[string]string) {
+	input.JobID = params["jobId"]
+}
@@ -0,0 +1,108 @@
+// Package service implements the HTTP handlers for the video transcoding
+// API.
+package service
+
+import (
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/nytm/video-transcoding-api/config"
+	"github.com/nytm/video-transcoding-api/db"
+	"github.com/nytm/video-transcoding-api/notification"
+	"github.com/nytm/video-transcoding-api/provider"
+)
+
+// TranscodingService is the gizmo JSONService responsible for handling
+// transcoding jobs and presets.
+type TranscodingService struct {
+	config   *config.Config
+	db       db.Storage
+	notifier notification.Notifier
+
+	ingestCancelMu sync.Mutex
+	ingestCancel   map[string]context.CancelFunc
+}
+
+// NewTranscodingService creates a new instance of the transcoding service.
+func NewTranscodingService(cfg *config.Config, dataStorage db.Storage, notifier notification.Notifier) (*TranscodingService, error) {
+	return &TranscodingService{
+		config:       cfg,
+		db:           dataStorage,
+		notifier:     notifier,
+		ingestCancel: make(map[string]context.CancelFunc),
+	}, nil
+}
+
+// registerIngestCancel records the cancel func for a job's in-flight
+// background ingestion, so cancelTranscodeJob can stop it.
+func (s *TranscodingService) registerIngestCancel(jobID string, cancel context.CancelFunc) {
+	s.ingestCancelMu.Lock()
+	s.ingestCancel[jobID] = cancel
+	s.ingestCancelMu.Unlock()
+}
+
+// clearIngestCancel drops jobID's cancel func once its ingestion has
+// finished, successfully or not, so the map doesn't grow unbounded.
+func (s *TranscodingService) clearIngestCancel(jobID string) {
+	s.ingestCancelMu.Lock()
+	delete(s.ingestCancel, jobID)
+	s.ingestCancelMu.Unlock()
+}
+
+// cancelIngest stops jobID's in-flight background ingestion, if any, and
+// reports whether one was found.
+func (s *TranscodingService) cancelIngest(jobID string) bool {
+	s.ingestCancelMu.Lock()
+	cancel, ok := s.ingestCancel[jobID]
+	s.ingestCancelMu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// gizmoResponse is returned by every handler in this service.
+type gizmoResponse struct {
+	status int
+	body   interface{}
+}
+
+// StatusCode returns the HTTP status code to be written in the response.
+func (r gizmoResponse) StatusCode() int {
+	return r.status
+}
+
+// Result returns the body to be marshaled into the response.
+func (r gizmoResponse) Result() interface{} {
+	return r.body
+}
+
+func newInvalidJobResponse(err error) gizmoResponse {
+	return gizmoResponse{status: http.StatusBadRequest, body: map[string]string{"error": err.Error()}}
+}
+
+func newErrorResponse(err error) gizmoResponse {
+	return gizmoResponse{status: http.StatusInternalServerError, body: map[string]string{"error": err.Error()}}
+}
+
+func newJobNotFoundResponse(err error) gizmoResponse {
+	return gizmoResponse{status: http.StatusNotFound, body: map[string]string{"error": err.Error()}}
+}
+
+func newJobNotFoundProviderResponse(err error) gizmoResponse {
+	return gizmoResponse{status: http.StatusGone, body: map[string]string{"error": err.Error()}}
+}
+
+func newJobAlreadyFinishedResponse(err error) gizmoResponse {
+	return gizmoResponse{status: http.StatusConflict, body: map[string]string{"error": err.Error()}}
+}
+
+func newJobResponse(jobID string) gizmoResponse {
+	return gizmoResponse{status: http.StatusOK, body: map[string]string{"jobId": jobID}}
+}
+
+func newJobStatusResponse(status *provider.JobStatus) gizmoResponse {
+	return gizmoResponse{status: http.StatusOK, body: status}
+}
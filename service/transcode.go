@@ -1,20 +1,17 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/gorilla/mux"
+	"golang.org/x/net/context"
+
 	"github.com/nytm/video-transcoding-api/db"
 	"github.com/nytm/video-transcoding-api/provider"
-	"golang.org/x/net/context"
+	"github.com/nytm/video-transcoding-api/provider/ingest"
 )
 
-const maxJobTimeout = 8 * time.Hour
-
 // swagger:route POST /jobs jobs newJob
 //
 // Creates a new transcoding job.
@@ -49,26 +46,29 @@ func (s *TranscodingService) newTranscodeJob(r *http.Request) gizmoResponse {
 		}
 		presets[i] = *preset
 	}
+	sources := make([]provider.Source, len(input.Payload.Sources))
+	for i, source := range input.Payload.Sources {
+		sources[i] = provider.Source{
+			SourceMedia: source.SourceMedia,
+			StartTime:   source.StartTime,
+			Duration:    source.Duration,
+			FrameRate:   source.FrameRate,
+			Container:   source.Container,
+		}
+	}
 	transcodeProfile := provider.TranscodeProfile{
 		SourceMedia:     input.Payload.Source,
+		Sources:         sources,
 		Presets:         presets,
 		StreamingParams: input.Payload.StreamingParams,
 	}
-	jobStatus, err := providerObj.Transcode(transcodeProfile)
-	if err == provider.ErrPresetNotFound {
-		return newInvalidJobResponse(err)
-	}
-	if err != nil {
-		providerError := fmt.Errorf("Error with provider %q: %s", input.Payload.Provider, err)
-		return newErrorResponse(providerError)
-	}
-	jobStatus.ProviderName = input.Payload.Provider
 	job := db.Job{
-		ProviderName:           jobStatus.ProviderName,
-		ProviderJobID:          jobStatus.ProviderJobID,
+		ProviderName:           input.Payload.Provider,
+		SourceMedia:            input.Payload.Source,
 		StatusCallbackURL:      input.Payload.StatusCallbackURL,
 		StatusCallbackInterval: input.Payload.StatusCallbackInterval,
 		CompletionCallbackURL:  input.Payload.CompletionCallbackURL,
+		CallbackSecret:         input.Payload.CallbackSecret,
 	}
 	if transcodeProfile.StreamingParams.Protocol != "" {
 		job.StreamingParams = db.StreamingParams{
@@ -76,18 +76,117 @@ func (s *TranscodingService) newTranscodeJob(r *http.Request) gizmoResponse {
 			Protocol:        transcodeProfile.StreamingParams.Protocol,
 		}
 	}
-	err = s.db.CreateJob(&job)
+	if transcodeProfile.SourceMedia != "" && !ingest.IsS3Source(transcodeProfile.SourceMedia) {
+		job.IngestStatus = db.IngestStatusFetching
+	}
+	if err = s.db.CreateJob(&job); err != nil {
+		return newErrorResponse(err)
+	}
+	if job.IngestStatus == db.IngestStatusFetching {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.registerIngestCancel(job.ID, cancel)
+		go s.ingestAndTranscode(ctx, job, providerObj, transcodeProfile)
+		return newJobResponse(job.ID)
+	}
+	jobStatus, err := providerObj.Transcode(&job, transcodeProfile)
+	if err == provider.ErrPresetNotFound {
+		return newInvalidJobResponse(err)
+	}
 	if err != nil {
+		providerError := fmt.Errorf("Error with provider %q: %s", input.Payload.Provider, err)
+		return newErrorResponse(providerError)
+	}
+	job.ProviderJobID = jobStatus.ProviderJobID
+	if err = s.db.UpdateJob(job.ID, &job); err != nil {
 		return newErrorResponse(err)
 	}
 	if job.StatusCallbackURL != "" || job.CompletionCallbackURL != "" {
-		ctx, cancel := context.WithTimeout(context.Background(), maxJobTimeout)
-		defer cancel()
-		go s.statusCallback(ctx, job)
+		if err = s.notifier.Register(&job); err != nil {
+			return newErrorResponse(fmt.Errorf("error registering job %q for notifications: %s", job.ID, err))
+		}
 	}
 	return newJobResponse(job.ID)
 }
 
+// ingestAndTranscode runs in its own goroutine for a job whose source
+// needed pre-fetching (e.g. youtube:// or http://): it resolves the source
+// to S3, persisting the ingest outcome (IngestStatus, plus IngestProgress
+// and MediaInfo on success), and then starts transcoding. It runs in the background, rather than inline in
+// newTranscodeJob, so POST /jobs can return as soon as the job is
+// persisted instead of blocking on the download. Failures are recorded on
+// the job itself, since there's no HTTP response left to report them on.
+//
+// ctx is canceled by cancelTranscodeJob if the client cancels the job while
+// ingestion is still running. It's also consulted after ingestion finishes,
+// before transcoding starts, so a cancellation racing with a successful
+// ingest doesn't get clobbered by this goroutine's own stale write.
+func (s *TranscodingService) ingestAndTranscode(ctx context.Context, job db.Job, providerObj provider.TranscodingProvider, transcodeProfile provider.TranscodeProfile) {
+	s3URI, err := s.ingestSource(ctx, &job, transcodeProfile.SourceMedia)
+	s.clearIngestCancel(job.ID)
+	if err != nil {
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	transcodeProfile.SourceMedia = s3URI
+	jobStatus, err := providerObj.Transcode(&job, transcodeProfile)
+	if err != nil {
+		job.Status = db.JobStatusFailed
+		s.db.UpdateJob(job.ID, &job)
+		return
+	}
+	if ctx.Err() != nil {
+		// The job was canceled while Transcode was starting. Stop the
+		// provider-side job rather than persisting ProviderJobID over
+		// the cancellation that's already been written to storage.
+		providerObj.CancelJob(jobStatus.ProviderJobID)
+		return
+	}
+	job.ProviderJobID = jobStatus.ProviderJobID
+	if err = s.db.UpdateJob(job.ID, &job); err != nil {
+		return
+	}
+	if job.StatusCallbackURL != "" || job.CompletionCallbackURL != "" {
+		s.notifier.Register(&job)
+	}
+}
+
+// ingestSource resolves a non-s3 job source (e.g. youtube:// or http://) to
+// an s3:// URI and persists the outcome on the job: IngestStatus becomes
+// finished (with IngestProgress set to 100 and the resolved MediaInfo) or
+// failed. IngestProgress isn't updated while the fetch is in flight, so
+// GET /jobs/{id} reports the fetching phase with 0 progress until it ends.
+// ctx is canceled if the client cancels the job while the fetch is still in
+// flight.
+func (s *TranscodingService) ingestSource(ctx context.Context, job *db.Job, sourceURI string) (string, error) {
+	fetcher, err := ingest.NewFetcher(s.config.Ingest, sourceURI)
+	if err != nil {
+		job.IngestStatus = db.IngestStatusFailed
+		job.Status = db.JobStatusFailed
+		s.db.UpdateJob(job.ID, job)
+		return "", err
+	}
+	s3URI, mediaInfo, err := fetcher.Fetch(ctx, sourceURI)
+	if err != nil {
+		job.IngestStatus = db.IngestStatusFailed
+		job.Status = db.JobStatusFailed
+		s.db.UpdateJob(job.ID, job)
+		return "", err
+	}
+	job.IngestStatus = db.IngestStatusFinished
+	job.IngestProgress = 100
+	job.MediaInfo = db.MediaInfo{
+		Duration: mediaInfo.Duration,
+		Width:    mediaInfo.Width,
+		Height:   mediaInfo.Height,
+	}
+	if err = s.db.UpdateJob(job.ID, job); err != nil {
+		return "", err
+	}
+	return s3URI, nil
+}
+
 // swagger:route GET /jobs/{jobId} jobs getJob
 //
 // Finds a trancode job using its ID.
@@ -129,6 +228,32 @@ func (s *TranscodingService) getTranscodeJobByID(jobID string) (*db.Job, *provid
 		}
 		return nil, nil, nil, fmt.Errorf("error retrieving job with id %q: %s", jobID, err)
 	}
+	if job.IngestStatus == db.IngestStatusFetching {
+		return job, &provider.JobStatus{
+			ProviderName: job.ProviderName,
+			Status:       provider.StatusQueued,
+			Progress:     job.IngestProgress,
+			ProviderStatus: map[string]interface{}{
+				"ingestStatus": job.IngestStatus,
+			},
+		}, nil, nil
+	}
+	if job.Status == db.JobStatusCanceled {
+		return job, &provider.JobStatus{
+			ProviderName: job.ProviderName,
+			Status:       provider.StatusCanceled,
+			Progress:     100,
+		}, nil, nil
+	}
+	if job.Status == db.JobStatusFailed {
+		return job, &provider.JobStatus{
+			ProviderName: job.ProviderName,
+			Status:       provider.StatusFailed,
+			ProviderStatus: map[string]interface{}{
+				"ingestStatus": job.IngestStatus,
+			},
+		}, nil, nil
+	}
 	providerFactory, err := provider.GetProviderFactory(job.ProviderName)
 	if err != nil {
 		return job, nil, nil, fmt.Errorf("unknown provider %q for job id %q", job.ProviderName, jobID)
@@ -137,7 +262,7 @@ func (s *TranscodingService) getTranscodeJobByID(jobID string) (*db.Job, *provid
 	if err != nil {
 		return job, nil, nil, fmt.Errorf("error initializing provider %q on job id %q: %s %s", job.ProviderName, jobID, providerObj, err)
 	}
-	jobStatus, err := providerObj.JobStatus(job.ProviderJobID)
+	jobStatus, err := providerObj.JobStatus(job)
 	if err != nil {
 		return job, nil, providerObj, err
 	}
@@ -145,49 +270,44 @@ func (s *TranscodingService) getTranscodeJobByID(jobID string) (*db.Job, *provid
 	return job, jobStatus, providerObj, nil
 }
 
-func (s *TranscodingService) statusCallback(ctx context.Context, job db.Job) error {
-	deadline, _ := ctx.Deadline()
-	for now := time.Now(); now.Before(deadline); now = time.Now() {
-		job, jobStatus, providerObj, err := s.getTranscodeJobByID(job.ID)
-		gizmoResponseObj := s.getJobStatusResponse(job, jobStatus, providerObj, err)
-		if job.StatusCallbackURL != "" {
-			err := s.postStatusToCallback(gizmoResponseObj, job.StatusCallbackURL)
-			if err != nil {
-				continue
-			}
-		}
-		if jobStatus.Status != provider.StatusQueued &&
-			jobStatus.Status != provider.StatusStarted {
-			if job.CompletionCallbackURL != "" {
-				err := s.postStatusToCallback(gizmoResponseObj, job.CompletionCallbackURL)
-				if err != nil {
-					continue
-				}
-			}
-			break
+// swagger:route DELETE /jobs/{jobId} jobs cancelJob
+//
+// Cancels a transcoding job.
+//
+//     Responses:
+//       200: jobStatus
+//       404: jobNotFound
+//       409: jobAlreadyFinished
+//       500: genericError
+func (s *TranscodingService) cancelTranscodeJob(r *http.Request) gizmoResponse {
+	var params getTranscodeJobInput
+	params.loadParams(mux.Vars(r))
+	job, jobStatus, providerObj, err := s.getTranscodeJobByID(params.JobID)
+	if err != nil {
+		if err == db.ErrJobNotFound {
+			return newJobNotFoundResponse(err)
 		}
-		time.Sleep(time.Duration(job.StatusCallbackInterval) * time.Second)
+		return newErrorResponse(err)
 	}
-	return nil
-}
-
-func (s *TranscodingService) postStatusToCallback(payloadStruct gizmoResponse, callbackURL string) error {
-	jsonPayload, err := json.Marshal(payloadStruct)
-	if err != nil {
-		fmt.Printf("Error generating response for status callback: %v", err)
-		return err
+	if jobStatus.Status.Terminal() {
+		return newJobAlreadyFinishedResponse(fmt.Errorf("job %q is already %s", job.ID, jobStatus.Status))
 	}
-	req, err := http.NewRequest("POST", callbackURL, bytes.NewBuffer(jsonPayload))
-	req.Header.Set("Content-Type", "application/json")
-	timeout := time.Duration(5 * time.Second)
-	client := &http.Client{
-		Timeout: timeout,
+	if job.IngestStatus == db.IngestStatusFetching {
+		s.cancelIngest(job.ID)
 	}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Printf("Error calling status callback URL %s : %v", callbackURL, err)
-		return err
+	if providerObj != nil {
+		if err = providerObj.CancelJob(job.ProviderJobID); err != nil && err != provider.ErrNotImplemented {
+			return newErrorResponse(fmt.Errorf("error canceling job %q on provider %q: %s", job.ID, job.ProviderName, err))
+		}
 	}
-	resp.Body.Close()
-	return nil
+	job.Status = db.JobStatusCanceled
+	if err = s.db.UpdateJob(job.ID, job); err != nil {
+		return newErrorResponse(err)
+	}
+	if err = s.notifier.Unregister(job.ID); err != nil {
+		return newErrorResponse(fmt.Errorf("error unregistering job %q from notifications: %s", job.ID, err))
+	}
+	jobStatus.Status = provider.StatusCanceled
+	jobStatus.Progress = 100
+	return newJobStatusResponse(jobStatus)
 }